@@ -0,0 +1,3 @@
+module 0xADE/xpass
+
+go 1.22
@@ -0,0 +1,217 @@
+// Package hibp checks passwords against the Have I Been Pwned password
+// corpus using the k-anonymity range API: only the first five hex characters
+// of the password's SHA-1 hash ever leave the machine, the suffixes returned
+// for that prefix are compared locally.
+package hibp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single range request when the Checker has none.
+const DefaultTimeout = 10 * time.Second
+
+const rangeURL = "https://api.pwnedpasswords.com/range/"
+
+// ErrUnavailable is returned when the API can't be reached, e.g. when the
+// machine is offline or the request timed out.
+var ErrUnavailable = errors.New("breach check service unavailable")
+
+// Fetcher retrieves the range API response for a five character hash prefix.
+// The response lists one "SUFFIX:COUNT" pair per line.
+type Fetcher interface {
+	Range(ctx context.Context, prefix string) (io.ReadCloser, error)
+}
+
+// HTTPFetcher queries the public range API.
+type HTTPFetcher struct {
+	Client *http.Client
+	// URL is the range endpoint the prefix is appended to. It defaults to
+	// the public API.
+	URL string
+}
+
+// Range implements Fetcher.
+func (f HTTPFetcher) Range(ctx context.Context, prefix string) (io.ReadCloser, error) {
+	url := f.URL
+	if url == "" {
+		url = rangeURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Padding hides the real number of suffixes for the prefix from
+	// anybody observing the response size.
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "xpass")
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Let the caller tell cancellation and timeouts from an
+		// unreachable service.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Checker looks up passwords through a Fetcher.
+type Checker struct {
+	Fetcher Fetcher
+	Timeout time.Duration
+}
+
+// New returns a Checker for the public API with the given request timeout.
+func New(timeout time.Duration) *Checker {
+	return &Checker{Fetcher: HTTPFetcher{}, Timeout: timeout}
+}
+
+// Count returns how many times the password appears in known breaches.
+// Zero means it wasn't found.
+func (c *Checker) Count(ctx context.Context, password string) (int, error) {
+	prefix, suffix := hashParts(password)
+	counts, err := c.fetch(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return counts[suffix], nil
+}
+
+// Report is the outcome of CheckAll.
+type Report struct {
+	// Breached maps entry names to their breach count. Entries that
+	// weren't found are not listed.
+	Breached map[string]int
+	// Checked is the number of entries looked up successfully.
+	Checked int
+	// Errors holds the entries that couldn't be checked.
+	Errors map[string]error
+}
+
+// CheckAll checks every password in the map, keyed by entry name. Entries
+// sharing a hash prefix are resolved with a single request. It stops early
+// when ctx is cancelled; the entries not reached are reported in Errors.
+func (c *Checker) CheckAll(ctx context.Context, passwords map[string]string) Report {
+	report := Report{
+		Breached: make(map[string]int),
+		Errors:   make(map[string]error),
+	}
+
+	type entry struct{ name, suffix string }
+	byPrefix := make(map[string][]entry)
+	for name, password := range passwords {
+		prefix, suffix := hashParts(password)
+		byPrefix[prefix] = append(byPrefix[prefix], entry{name, suffix})
+	}
+
+	for prefix, entries := range byPrefix {
+		var (
+			counts map[string]int
+			err    error
+		)
+		// Don't start new requests once cancelled, but keep the results
+		// of requests that already completed.
+		if err = ctx.Err(); err == nil {
+			counts, err = c.fetch(ctx, prefix)
+		}
+		for _, e := range entries {
+			if err != nil {
+				report.Errors[e.name] = err
+				continue
+			}
+			report.Checked++
+			if n := counts[e.suffix]; n > 0 {
+				report.Breached[e.name] = n
+			}
+		}
+	}
+	return report
+}
+
+func (c *Checker) fetch(ctx context.Context, prefix string) (map[string]int, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := c.Fetcher.Range(reqCtx, prefix)
+	if err != nil {
+		// A request running into its own timeout means the service is
+		// unavailable; the caller's cancellation is passed on as is.
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil && !errors.Is(err, ErrUnavailable) {
+			err = fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		return nil, err
+	}
+	defer body.Close()
+	return parseRange(body)
+}
+
+// parseRange reads a range response. Every line must be a 35 character hex
+// suffix, a colon and a count; anything else means the response can't be
+// trusted to be complete, so it is an error rather than "not breached".
+func parseRange(r io.Reader) (map[string]int, error) {
+	counts := make(map[string]int)
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		suffix, count, ok := strings.Cut(line, ":")
+		n, err := strconv.Atoi(count)
+		if !ok || len(suffix) != 35 || !isHex(suffix) || err != nil || n < 0 {
+			return nil, fmt.Errorf("malformed range response line %q", line)
+		}
+		// Padding entries carry a zero count.
+		if n > 0 {
+			counts[strings.ToUpper(suffix)] = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	// Every prefix has hundreds of suffixes, and padding adds more.
+	if lines == 0 {
+		return nil, fmt.Errorf("%w: empty range response", ErrUnavailable)
+	}
+	return counts, nil
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'A' <= c && c <= 'F' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func hashParts(password string) (prefix, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return h[:5], h[5:]
+}
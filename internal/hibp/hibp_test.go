@@ -0,0 +1,163 @@
+package hibp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// "password" hashes to 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+const passwordRange = "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n" +
+	"1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n" +
+	"011053FD0102E94D6AE2F8B83D76FAF94F6:0\r\n"
+
+// paddingRange stands in for the response to any other prefix.
+const paddingRange = "011053FD0102E94D6AE2F8B83D76FAF94F6:0\r\n"
+
+type stubFetcher struct {
+	ranges map[string]string
+	calls  []string
+	block  bool
+	after  func()
+}
+
+func (f *stubFetcher) Range(ctx context.Context, prefix string) (io.ReadCloser, error) {
+	f.calls = append(f.calls, prefix)
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if f.after != nil {
+		f.after()
+	}
+	body, ok := f.ranges[prefix]
+	if !ok {
+		body = paddingRange
+	}
+	return io.NopCloser(strings.NewReader(body)), nil
+}
+
+func TestCount(t *testing.T) {
+	c := &Checker{Fetcher: &stubFetcher{ranges: map[string]string{"5BAA6": passwordRange}}}
+
+	n, err := c.Count(context.Background(), "password")
+	if err != nil || n != 3861493 {
+		t.Fatalf("Count(password) = %d, %v; want 3861493", n, err)
+	}
+	n, err = c.Count(context.Background(), "not in the corpus")
+	if err != nil || n != 0 {
+		t.Fatalf("Count(unknown) = %d, %v; want 0", n, err)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	counts, err := parseRange(strings.NewReader(passwordRange))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 {
+		t.Errorf("padding lines with a zero count were kept: %v", counts)
+	}
+	if counts["1E4C9B93F3F0682250B6CF8331B7EE68FD8"] != 3861493 {
+		t.Errorf("CRLF line not parsed: %v", counts)
+	}
+
+	_, err = parseRange(strings.NewReader("ZZ:x\n"))
+	if err == nil || !strings.Contains(err.Error(), `"ZZ:x"`) {
+		t.Errorf("malformed line error = %v, want it to quote the line", err)
+	}
+
+	for _, body := range []string{
+		"<html>rate limited</html>\n",
+		"1E4C9B93F3F0682250B6CF8331B7EE68FD8\n",
+		"1E4C9B93F3F0682250B6CF8331B7EE68FD:3\n",
+		"1E4C9B93F3F0682250B6CF8331B7EE68FDX:3\n",
+		"1E4C9B93F3F0682250B6CF8331B7EE68FD8:-3\n",
+		passwordRange + "\r\n",
+	} {
+		if _, err := parseRange(strings.NewReader(body)); err == nil {
+			t.Errorf("parseRange(%q) succeeded, want an error", body)
+		}
+	}
+	if _, err := parseRange(strings.NewReader("")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("parseRange(empty) error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" {
+			t.Errorf("request path = %q, want only the prefix", r.URL.Path)
+		}
+		if r.Header.Get("Add-Padding") != "true" {
+			t.Error("Add-Padding header not sent")
+		}
+		w.WriteHeader(int(status.Load()))
+		io.WriteString(w, passwordRange)
+	}))
+	defer srv.Close()
+	c := &Checker{Fetcher: HTTPFetcher{Client: srv.Client(), URL: srv.URL + "/range/"}}
+
+	n, err := c.Count(context.Background(), "password")
+	if err != nil || n != 3861493 {
+		t.Fatalf("Count(password) = %d, %v; want 3861493", n, err)
+	}
+
+	status.Store(http.StatusTooManyRequests)
+	if _, err := c.Count(context.Background(), "password"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Count error on HTTP 429 = %v, want ErrUnavailable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Count(ctx, "password"); !errors.Is(err, context.Canceled) || errors.Is(err, ErrUnavailable) {
+		t.Errorf("Count error when cancelled = %v, want context.Canceled", err)
+	}
+}
+
+func TestTimeoutIsUnavailable(t *testing.T) {
+	c := &Checker{Fetcher: &stubFetcher{block: true}, Timeout: 10 * time.Millisecond}
+	if _, err := c.Count(context.Background(), "password"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Count error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	f := &stubFetcher{ranges: map[string]string{"5BAA6": passwordRange}}
+	c := &Checker{Fetcher: f}
+
+	r := c.CheckAll(context.Background(), map[string]string{
+		"web/a": "password",
+		"web/b": "password",
+		"web/c": "something else",
+	})
+	if len(f.calls) != 2 {
+		t.Errorf("made %d requests, want one per prefix (2): %v", len(f.calls), f.calls)
+	}
+	if r.Checked != 3 || len(r.Errors) != 0 {
+		t.Errorf("Checked = %d, Errors = %v; want 3 checked, no errors", r.Checked, r.Errors)
+	}
+	if len(r.Breached) != 2 || r.Breached["web/a"] != 3861493 || r.Breached["web/b"] != 3861493 {
+		t.Errorf("Breached = %v", r.Breached)
+	}
+}
+
+func TestCheckAllKeepsCompletedResultOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := &stubFetcher{ranges: map[string]string{"5BAA6": passwordRange}, after: cancel}
+	c := &Checker{Fetcher: f}
+
+	r := c.CheckAll(ctx, map[string]string{"web/a": "password"})
+	if r.Checked != 1 || r.Breached["web/a"] != 3861493 || len(r.Errors) != 0 {
+		t.Errorf("report = %+v, want the fetched result kept", r)
+	}
+}
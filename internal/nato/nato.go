@@ -0,0 +1,101 @@
+// Package nato spells passwords out with the NATO phonetic alphabet so they
+// can be read aloud without ambiguity.
+package nato
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Line is the spelling of a single character.
+type Line struct {
+	Char rune
+	Word string
+}
+
+// String formats the line as "a — alfa".
+func (l Line) String() string {
+	if !unicode.IsPrint(l.Char) || l.Char == ' ' {
+		return l.Word
+	}
+	return string(l.Char) + " — " + l.Word
+}
+
+// Spell returns one line per character of s.
+func Spell(s string) []Line {
+	lines := make([]Line, 0, len(s))
+	for _, r := range s {
+		lines = append(lines, Line{Char: r, Word: Word(r)})
+	}
+	return lines
+}
+
+// Word returns the spoken form of r. Capital letters are written in upper
+// case and marked as such so the case is explicit when read aloud.
+// Characters outside printable ASCII are described by their code point.
+func Word(r rune) string {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return letters[r-'a']
+	case r >= 'A' && r <= 'Z':
+		return strings.ToUpper(letters[r-'A']) + " (capital)"
+	case r >= '0' && r <= '9':
+		return digits[r-'0']
+	}
+	if w, ok := symbols[r]; ok {
+		return w
+	}
+	if unicode.IsPrint(r) {
+		return fmt.Sprintf("unicode U+%04X", r)
+	}
+	return fmt.Sprintf("non-printable U+%04X", r)
+}
+
+var letters = [26]string{
+	"alfa", "bravo", "charlie", "delta", "echo", "foxtrot", "golf",
+	"hotel", "india", "juliett", "kilo", "lima", "mike", "november",
+	"oscar", "papa", "quebec", "romeo", "sierra", "tango", "uniform",
+	"victor", "whiskey", "x-ray", "yankee", "zulu",
+}
+
+var digits = [10]string{
+	"zero", "one", "two", "three", "four",
+	"five", "six", "seven", "eight", "nine",
+}
+
+var symbols = map[rune]string{
+	' ':  "space",
+	'!':  "exclamation mark",
+	'"':  "double quote",
+	'#':  "hash",
+	'$':  "dollar",
+	'%':  "percent",
+	'&':  "ampersand",
+	'\'': "apostrophe",
+	'(':  "left parenthesis",
+	')':  "right parenthesis",
+	'*':  "asterisk",
+	'+':  "plus",
+	',':  "comma",
+	'-':  "hyphen",
+	'.':  "period",
+	'/':  "slash",
+	':':  "colon",
+	';':  "semicolon",
+	'<':  "less than",
+	'=':  "equals",
+	'>':  "greater than",
+	'?':  "question mark",
+	'@':  "at sign",
+	'[':  "left bracket",
+	'\\': "backslash",
+	']':  "right bracket",
+	'^':  "caret",
+	'_':  "underscore",
+	'`':  "backtick",
+	'{':  "left brace",
+	'|':  "vertical bar",
+	'}':  "right brace",
+	'~':  "tilde",
+}
@@ -0,0 +1,45 @@
+package nato
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordCoversPrintableASCII(t *testing.T) {
+	for r := rune(' '); r <= '~'; r++ {
+		w := Word(r)
+		if w == "" || strings.Contains(w, "U+") {
+			t.Errorf("Word(%q) = %q, want a name", r, w)
+		}
+	}
+}
+
+func TestSpell(t *testing.T) {
+	want := []string{
+		"a — alfa",
+		"R — ROMEO (capital)",
+		"4 — four",
+		"# — hash",
+		"space",
+		"é — unicode U+00E9",
+		"non-printable U+0009",
+	}
+	lines := Spell("aR4# é\t")
+	if len(lines) != len(want) {
+		t.Fatalf("Spell returned %d lines, want %d", len(lines), len(want))
+	}
+	for i, l := range lines {
+		if got := l.String(); got != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestWordCapitals(t *testing.T) {
+	for r := 'A'; r <= 'Z'; r++ {
+		lower := Word(r + 'a' - 'A')
+		if got, want := Word(r), strings.ToUpper(lower)+" (capital)"; got != want {
+			t.Errorf("Word(%q) = %q, want %q", r, got, want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package passgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Defaults used by pass when PASSWORD_STORE_CHARACTER_SET and
+// PASSWORD_STORE_CHARACTER_SET_NO_SYMBOLS are unset.
+const (
+	PassCharset          = "[:punct:][:alnum:]"
+	PassCharsetNoSymbols = "[:alnum:]"
+)
+
+// PassLength is pass's default for PASSWORD_STORE_GENERATED_LENGTH.
+const PassLength = 25
+
+// PassOptions returns the generator settings pass would use, reading
+// PASSWORD_STORE_GENERATED_LENGTH, PASSWORD_STORE_CHARACTER_SET and
+// PASSWORD_STORE_CHARACTER_SET_NO_SYMBOLS through getenv, usually os.Getenv.
+// noSymbols selects the second set, like `pass generate -n`. Unset or empty
+// variables take pass's defaults; invalid ones are reported in warnings and
+// replaced by the defaults, so the returned options are always valid.
+func PassOptions(getenv func(string) string, noSymbols bool) (Options, []error) {
+	var warnings []error
+	opts := Options{Length: PassLength}
+
+	if v := getenv("PASSWORD_STORE_GENERATED_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxLength {
+			warnings = append(warnings, fmt.Errorf("%w: PASSWORD_STORE_GENERATED_LENGTH=%q, must be between 1 and %d; using %d",
+				ErrLength, v, MaxLength, PassLength))
+		} else {
+			opts.Length = n
+		}
+	}
+
+	name, def := "PASSWORD_STORE_CHARACTER_SET", PassCharset
+	if noSymbols {
+		name, def = "PASSWORD_STORE_CHARACTER_SET_NO_SYMBOLS", PassCharsetNoSymbols
+	}
+	spec := def
+	if v := getenv(name); v != "" {
+		spec = v
+	}
+	charset, err := ParseCharset(spec)
+	if err != nil {
+		warnings = append(warnings, fmt.Errorf("%s=%q: %w; using %q", name, spec, err, def))
+		charset, _ = ParseCharset(def)
+	}
+	opts.Charset = charset
+	return opts, warnings
+}
+
+var posixClasses = map[string]string{
+	"alnum":  Upper + Lower + Digits,
+	"alpha":  Upper + Lower,
+	"blank":  " \t",
+	"cntrl":  cntrl(),
+	"digit":  Digits,
+	"graph":  Symbols + Upper + Lower + Digits,
+	"lower":  Lower,
+	"print":  " " + Symbols + Upper + Lower + Digits,
+	"punct":  Symbols,
+	"space":  " \t\n\v\f\r",
+	"upper":  Upper,
+	"xdigit": Digits + "ABCDEFabcdef",
+}
+
+func cntrl() string {
+	var b strings.Builder
+	for c := byte(0); c < ' '; c++ {
+		b.WriteByte(c)
+	}
+	b.WriteByte(0x7F)
+	return b.String()
+}
+
+// ParseCharset expands a character set written the way pass passes it to
+// `tr -dc`: literal characters, ranges like a-z, POSIX classes like
+// [:alnum:] and backslash escapes (\\, \-, \[ and octal \NNN), so that
+// `a\-z` means the three characters a, - and z. Duplicates are dropped.
+// Only printable ASCII is accepted, since anything else would produce
+// passwords that can't be typed reliably; classes such as [:space:] and
+// [:cntrl:] parse but are rejected for holding control characters.
+func ParseCharset(spec string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(spec); {
+		if strings.HasPrefix(spec[i:], "[:") {
+			end := strings.Index(spec[i+2:], ":]")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class in %q", spec)
+			}
+			name := spec[i+2 : i+2+end]
+			class, ok := posixClasses[name]
+			if !ok {
+				return "", fmt.Errorf("unsupported character class [:%s:]", name)
+			}
+			b.WriteString(class)
+			i += end + 4
+			continue
+		}
+
+		lo, n := charAt(spec, i)
+		i += n
+		// An unescaped hyphen between two characters forms a range; a
+		// leading or trailing hyphen is literal.
+		if i+1 < len(spec) && spec[i] == '-' {
+			hi, n := charAt(spec, i+1)
+			if lo > hi {
+				return "", fmt.Errorf("invalid range %q-%q in %q", lo, hi, spec)
+			}
+			for c := int(lo); c <= int(hi); c++ {
+				b.WriteByte(byte(c))
+			}
+			i += 1 + n
+			continue
+		}
+		b.WriteByte(lo)
+	}
+	return literalCharset(b.String())
+}
+
+// charAt returns the character at spec[i], resolving a backslash escape,
+// and the number of bytes it occupies.
+func charAt(spec string, i int) (byte, int) {
+	if spec[i] != '\\' || i+1 == len(spec) {
+		// A trailing backslash stands for itself, as in tr.
+		return spec[i], 1
+	}
+	// Like tr, stop before a digit that would take the value past \377,
+	// so \400 is \40 followed by 0.
+	n := 1
+	v := 0
+	for n <= 3 && i+n < len(spec) && spec[i+n] >= '0' && spec[i+n] <= '7' {
+		next := v*8 + int(spec[i+n]-'0')
+		if next > 0377 {
+			break
+		}
+		v = next
+		n++
+	}
+	if n > 1 {
+		return byte(v), n
+	}
+	if c, ok := controlEscapes[spec[i+1]]; ok {
+		return c, 2
+	}
+	return spec[i+1], 2
+}
+
+// controlEscapes are tr's escapes for control characters. They parse, but
+// literalCharset rejects them.
+var controlEscapes = map[byte]byte{
+	'a': '\a',
+	'b': '\b',
+	'f': '\f',
+	'n': '\n',
+	'r': '\r',
+	't': '\t',
+	'v': '\v',
+}
+
+// literalCharset checks that set only holds printable ASCII and removes
+// duplicates, which would otherwise bias sampling towards them.
+func literalCharset(set string) (string, error) {
+	var (
+		b    strings.Builder
+		seen [128]bool
+	)
+	for i := 0; i < len(set); i++ {
+		c := set[i]
+		if c < ' ' || c > '~' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidCharset, set)
+		}
+		if !seen[c] {
+			seen[c] = true
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return "", ErrEmptyCharset
+	}
+	return b.String(), nil
+}
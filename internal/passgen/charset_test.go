@@ -0,0 +1,190 @@
+package passgen
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCharset(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+		err  error
+	}{
+		{spec: "abc", want: "abc"},
+		{spec: "abcabc", want: "abc"},
+		{spec: "a-f", want: "abcdef"},
+		{spec: "a-c0-2", want: "abc012"},
+		{spec: "-ab", want: "-ab"},
+		{spec: "ab-", want: "ab-"},
+		{spec: `a\-z`, want: "a-z"},
+		{spec: `\\`, want: `\`},
+		{spec: `a\`, want: `a\`},
+		{spec: `\101\102`, want: "AB"},
+		{spec: `\400`, want: " 0"},
+		{spec: `\0410`, want: "!0"},
+		{spec: `\77`, want: "?"},
+		{spec: `\[:digit:]`, want: "[:digt]"},
+		{spec: "[:digit:]", want: Digits},
+		{spec: "[:xdigit:]", want: Digits + "ABCDEFabcdef"},
+		{spec: "[:graph:]", want: Symbols + Upper + Lower + Digits},
+		{spec: "[:print:]", want: " " + Symbols + Upper + Lower + Digits},
+		{spec: PassCharsetNoSymbols, want: Upper + Lower + Digits},
+		{spec: PassCharset, want: Symbols + Upper + Lower + Digits},
+		{spec: "[:digit:]x", want: Digits + "x"},
+		{spec: "", err: ErrEmptyCharset},
+		{spec: "é", err: ErrInvalidCharset},
+		{spec: `a\n`, err: ErrInvalidCharset},
+		{spec: "[:space:]", err: ErrInvalidCharset},
+		{spec: "[:blank:]", err: ErrInvalidCharset},
+		{spec: "[:cntrl:]", err: ErrInvalidCharset},
+		{spec: "z-a", err: errAny},
+		{spec: "[:alnum", err: errAny},
+		{spec: "[:foo:]", err: errAny},
+	}
+	for _, tt := range tests {
+		got, err := ParseCharset(tt.spec)
+		switch {
+		case tt.err == errAny:
+			if err == nil {
+				t.Errorf("ParseCharset(%q) = %q, want an error", tt.spec, got)
+			}
+		case tt.err != nil:
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParseCharset(%q) error = %v, want %v", tt.spec, err, tt.err)
+			}
+		case err != nil || got != tt.want:
+			t.Errorf("ParseCharset(%q) = %q, %v; want %q", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+var errAny = errors.New("any error")
+
+func TestGenerateCharset(t *testing.T) {
+	p, err := Generate(Options{Length: 1000, Charset: "abba"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := 0, 0
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case 'a':
+			a++
+		case 'b':
+			b++
+		default:
+			t.Fatalf("unexpected character %q", p[i])
+		}
+	}
+	// Duplicates must not bias sampling; 1000 fair coin flips stay
+	// within 400..600 with overwhelming probability.
+	if a < 400 || b < 400 {
+		t.Errorf("a = %d, b = %d, want roughly equal counts", a, b)
+	}
+
+	if _, err := Generate(Options{Length: 10, Charset: "é"}); !errors.Is(err, ErrInvalidCharset) {
+		t.Errorf("Generate with non-ASCII charset: err = %v, want ErrInvalidCharset", err)
+	}
+	if _, err := Generate(Options{Length: 10, Charset: "0O1", ExcludeAmbiguous: true}); !errors.Is(err, ErrEmptyCharset) {
+		t.Errorf("Generate with fully excluded charset: err = %v, want ErrEmptyCharset", err)
+	}
+}
+
+func TestLengthFollowsClassCount(t *testing.T) {
+	tests := []struct {
+		opts Options
+		ok   bool
+	}{
+		{Options{Length: 1, Charset: "ab"}, true},
+		{Options{Length: 0, Charset: "ab"}, false},
+		{Options{Length: 2, Lower: true, Digits: true}, true},
+		{Options{Length: 3, Upper: true, Lower: true, Digits: true, Symbols: true}, false},
+	}
+	for _, tt := range tests {
+		p, err := Generate(tt.opts)
+		if tt.ok && (err != nil || len(p) != tt.opts.Length) {
+			t.Errorf("Generate(%+v) = %q, %v", tt.opts, p, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrLength) {
+			t.Errorf("Generate(%+v) error = %v, want ErrLength", tt.opts, err)
+		}
+	}
+}
+
+func TestPassOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		noSymbols bool
+		want      Options
+		warnings  int
+	}{
+		{
+			name: "defaults",
+			want: Options{Length: PassLength, Charset: Symbols + Upper + Lower + Digits},
+		},
+		{
+			name:      "defaults without symbols",
+			noSymbols: true,
+			want:      Options{Length: PassLength, Charset: Upper + Lower + Digits},
+		},
+		{
+			name: "custom",
+			env: map[string]string{
+				"PASSWORD_STORE_GENERATED_LENGTH": "12",
+				"PASSWORD_STORE_CHARACTER_SET":    "a-f",
+			},
+			want: Options{Length: 12, Charset: "abcdef"},
+		},
+		{
+			name: "custom without symbols",
+			env: map[string]string{
+				"PASSWORD_STORE_CHARACTER_SET":            "a-f",
+				"PASSWORD_STORE_CHARACTER_SET_NO_SYMBOLS": "[:digit:]",
+			},
+			noSymbols: true,
+			want:      Options{Length: PassLength, Charset: Digits},
+		},
+		{
+			name:     "length not a number",
+			env:      map[string]string{"PASSWORD_STORE_GENERATED_LENGTH": "twelve"},
+			want:     Options{Length: PassLength, Charset: Symbols + Upper + Lower + Digits},
+			warnings: 1,
+		},
+		{
+			name:     "length zero",
+			env:      map[string]string{"PASSWORD_STORE_GENERATED_LENGTH": "0"},
+			want:     Options{Length: PassLength, Charset: Symbols + Upper + Lower + Digits},
+			warnings: 1,
+		},
+		{
+			name:     "length too long",
+			env:      map[string]string{"PASSWORD_STORE_GENERATED_LENGTH": "100000"},
+			want:     Options{Length: PassLength, Charset: Symbols + Upper + Lower + Digits},
+			warnings: 1,
+		},
+		{
+			name: "invalid charsets",
+			env: map[string]string{
+				"PASSWORD_STORE_GENERATED_LENGTH":         "-1",
+				"PASSWORD_STORE_CHARACTER_SET_NO_SYMBOLS": "[:space:]",
+			},
+			noSymbols: true,
+			want:      Options{Length: PassLength, Charset: Upper + Lower + Digits},
+			warnings:  2,
+		},
+	}
+	for _, tt := range tests {
+		got, warnings := PassOptions(func(k string) string { return tt.env[k] }, tt.noSymbols)
+		if got != tt.want {
+			t.Errorf("%s: PassOptions = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if len(warnings) != tt.warnings {
+			t.Errorf("%s: warnings = %v, want %d", tt.name, warnings, tt.warnings)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("%s: options don't validate: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package passgen generates random passwords from configurable character
+// classes.
+package passgen
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MaxLength is the upper limit for Options.Length. The lower limit is the
+// number of selected classes, since each of them contributes a character.
+const MaxLength = 1024
+
+// Character classes.
+const (
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Digits  = "0123456789"
+	Symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	// Ambiguous lists the characters that are easy to confuse with each
+	// other in many fonts.
+	Ambiguous = "0O1lI"
+)
+
+var (
+	// ErrNoClasses is returned when Options selects no character class.
+	ErrNoClasses = errors.New("no character classes selected")
+	// ErrEmptyCharset is returned when a character set has no characters
+	// left, e.g. after ExcludeAmbiguous.
+	ErrEmptyCharset = errors.New("character set is empty")
+	// ErrInvalidCharset is returned when a character set holds anything
+	// but printable ASCII.
+	ErrInvalidCharset = errors.New("character set must only contain printable ASCII")
+	// ErrLength is returned when Options.Length is below the number of
+	// selected classes or above MaxLength.
+	ErrLength = errors.New("invalid password length")
+)
+
+// Options controls Generate.
+type Options struct {
+	Length int
+
+	Upper   bool
+	Lower   bool
+	Digits  bool
+	Symbols bool
+
+	// ExcludeAmbiguous drops the characters in Ambiguous from every class.
+	ExcludeAmbiguous bool
+
+	// Charset, when not empty, replaces the classes above with a literal
+	// set of characters, as produced by ParseCharset. It may only contain
+	// printable ASCII; duplicates are ignored.
+	Charset string
+}
+
+// DefaultOptions returns the settings xpass used before the generator became
+// configurable: 16 letters and digits.
+func DefaultOptions() Options {
+	return Options{
+		Length: 16,
+		Upper:  true,
+		Lower:  true,
+		Digits: true,
+	}
+}
+
+// classes returns the character sets selected by the options.
+func (o Options) classes() ([]string, error) {
+	var classes []string
+	add := func(enabled bool, class string) {
+		if !enabled {
+			return
+		}
+		if o.ExcludeAmbiguous {
+			class = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, class)
+		}
+		classes = append(classes, class)
+	}
+	if o.Charset != "" {
+		charset, err := literalCharset(o.Charset)
+		if err != nil {
+			return nil, err
+		}
+		add(true, charset)
+		if len(classes[0]) == 0 {
+			return nil, ErrEmptyCharset
+		}
+		return classes, nil
+	}
+	add(o.Upper, Upper)
+	add(o.Lower, Lower)
+	add(o.Digits, Digits)
+	add(o.Symbols, Symbols)
+	if len(classes) == 0 {
+		return nil, ErrNoClasses
+	}
+	return classes, nil
+}
+
+// Validate reports whether Generate can satisfy the options.
+func (o Options) Validate() error {
+	_, err := o.validClasses()
+	return err
+}
+
+func (o Options) validClasses() ([]string, error) {
+	classes, err := o.classes()
+	if err != nil {
+		return nil, err
+	}
+	if o.Length < len(classes) || o.Length > MaxLength {
+		return nil, fmt.Errorf("%w: %d, must be between %d and %d",
+			ErrLength, o.Length, len(classes), MaxLength)
+	}
+	return classes, nil
+}
+
+// Generate returns a password of opts.Length characters containing at least
+// one character of every selected class. Characters are drawn uniformly
+// with crypto/rand.
+func Generate(opts Options) (string, error) {
+	classes, err := opts.validClasses()
+	if err != nil {
+		return "", err
+	}
+
+	password := make([]byte, 0, opts.Length)
+	for _, class := range classes {
+		c, err := pick(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	all := strings.Join(classes, "")
+	for len(password) < opts.Length {
+		c, err := pick(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// The guaranteed characters sit at the front; shuffle so that their
+	// positions are as random as the rest.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+func pick(set string) (byte, error) {
+	i, err := randInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+// randInt returns a uniform random number in [0, n).
+func randInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("reading random source: %w", err)
+	}
+	return int(v.Int64()), nil
+}
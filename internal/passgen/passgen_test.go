@@ -0,0 +1,100 @@
+package passgen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateClassGuarantee(t *testing.T) {
+	opts := Options{Length: 4, Upper: true, Lower: true, Digits: true, Symbols: true}
+	for i := 0; i < 1000; i++ {
+		p, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p) != opts.Length {
+			t.Fatalf("Generate returned %q, want %d characters", p, opts.Length)
+		}
+		for _, class := range []string{Upper, Lower, Digits, Symbols} {
+			if !strings.ContainsAny(p, class) {
+				t.Fatalf("%q has no character from %q", p, class)
+			}
+		}
+	}
+}
+
+func TestGenerateOnlySelectedClasses(t *testing.T) {
+	opts := Options{Length: 64, Lower: true, Digits: true}
+	for i := 0; i < 100; i++ {
+		p, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Trim(p, Lower+Digits) != "" {
+			t.Fatalf("%q contains characters outside the selected classes", p)
+		}
+	}
+}
+
+func TestGenerateExcludeAmbiguous(t *testing.T) {
+	opts := Options{Length: 256, Upper: true, Lower: true, Digits: true, ExcludeAmbiguous: true}
+	for i := 0; i < 100; i++ {
+		p, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsAny(p, Ambiguous) {
+			t.Fatalf("%q contains an ambiguous character", p)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want error
+	}{
+		{DefaultOptions(), nil},
+		{Options{Length: 0, Lower: true}, ErrLength},
+		{Options{Length: MaxLength + 1, Lower: true}, ErrLength},
+		{Options{Length: 16}, ErrNoClasses},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%+v) = %v, want %v", tt.opts, err, tt.want)
+		}
+		if _, err := Generate(tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("Generate(%+v) error = %v, want %v", tt.opts, err, tt.want)
+		}
+	}
+}
+
+// TestGenerateDistribution is a sanity check against gross sampling bias:
+// every digit should appear about equally often.
+func TestGenerateDistribution(t *testing.T) {
+	const samples = 100000
+	counts := make(map[rune]int)
+	opts := Options{Length: 1000, Digits: true}
+	for n := 0; n < samples; n += opts.Length {
+		p, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range p {
+			counts[r]++
+		}
+	}
+
+	expected := float64(samples) / float64(len(Digits))
+	var chi2 float64
+	for _, r := range Digits {
+		d := float64(counts[r]) - expected
+		chi2 += d * d / expected
+	}
+	// 9 degrees of freedom; 40 is far beyond the 0.9999 quantile (~33.7),
+	// so the test only fails on real bias.
+	if chi2 > 40 {
+		t.Errorf("chi-square %.1f over digits, counts %v", chi2, counts)
+	}
+}
@@ -0,0 +1,474 @@
+// Package qr encodes text as a QR code (ISO/IEC 18004) in byte mode.
+//
+// The result is a plain module matrix; drawing it is left to the caller so
+// that the secret being encoded never has to pass through an image file.
+package qr
+
+import (
+	"errors"
+)
+
+// Level is the error correction level.
+type Level int
+
+const (
+	L Level = iota // recovers ~7% of damaged modules
+	M              // ~15%
+	Q              // ~25%
+	H              // ~30%
+)
+
+var (
+	// ErrTooLong is returned when the text doesn't fit in a version 40
+	// code at the requested level.
+	ErrTooLong = errors.New("qr: text too long")
+	// ErrLevel is returned for a Level other than L, M, Q or H.
+	ErrLevel = errors.New("qr: invalid error correction level")
+)
+
+// Code is an encoded QR symbol without the quiet zone.
+type Code struct {
+	// Size is the number of modules on each side.
+	Size int
+	// Version is the symbol version, 1..40.
+	Version int
+	// Mask is the data mask pattern, 0..7.
+	Mask int
+
+	modules []bool
+}
+
+// Black reports whether the module at column x, row y is dark. Coordinates
+// outside the symbol are light, which makes drawing the quiet zone trivial.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Encode returns the smallest QR code holding text at the given level. The
+// mask is chosen by the standard penalty rules.
+func Encode(text string, level Level) (*Code, error) {
+	if level < L || level > H {
+		return nil, ErrLevel
+	}
+	data := []byte(text)
+	for version := 1; version <= 40; version++ {
+		if len(data) <= capacity(version, level) {
+			best := -1
+			var code *Code
+			for mask := 0; mask < 8; mask++ {
+				c := encode(data, version, level, mask)
+				if p := c.penalty(); best < 0 || p < best {
+					best, code = p, c
+				}
+			}
+			return code, nil
+		}
+	}
+	return nil, ErrTooLong
+}
+
+// capacity returns the number of bytes a byte mode segment can hold.
+func capacity(version int, level Level) int {
+	bits := dataCodewords(version, level)*8 - 4 - countBits(version)
+	return bits / 8
+}
+
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func encode(data []byte, version int, level Level, mask int) *Code {
+	size := version*4 + 17
+	c := &Code{
+		Size:    size,
+		Version: version,
+		Mask:    mask,
+		modules: make([]bool, size*size),
+	}
+	reserved := make([]bool, size*size)
+	c.drawFunctionPatterns(reserved, level)
+	c.drawCodewords(reserved, interleave(codewords(data, version, level), version, level))
+	c.applyMask(reserved)
+	c.drawFormat(reserved, level)
+	return c
+}
+
+func (c *Code) set(reserved []bool, x, y int, black bool) {
+	c.modules[y*c.Size+x] = black
+	reserved[y*c.Size+x] = true
+}
+
+func (c *Code) drawFunctionPatterns(reserved []bool, level Level) {
+	// Timing patterns.
+	for i := 0; i < c.Size; i++ {
+		c.set(reserved, 6, i, i%2 == 0)
+		c.set(reserved, i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with their separators.
+	for _, p := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := p[0]+dx, p[1]+dy
+				if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+					continue
+				}
+				d := max(abs(dx), abs(dy))
+				c.set(reserved, x, y, d != 2 && d != 4)
+			}
+		}
+	}
+
+	// Alignment patterns, skipping the three that overlap the finders.
+	pos := alignmentPositions(c.Version)
+	for i, y := range pos {
+		for j, x := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == len(pos)-1) || (i == len(pos)-1 && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(reserved, x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas now; they are filled in after masking.
+	c.drawFormat(reserved, level)
+
+	if c.Version >= 7 {
+		bits := versionBits(c.Version)
+		for i := 0; i < 18; i++ {
+			black := bits>>i&1 != 0
+			a, b := c.Size-11+i%3, i/3
+			c.set(reserved, a, b, black)
+			c.set(reserved, b, a, black)
+		}
+	}
+}
+
+// drawFormat writes both copies of the format information and the dark
+// module next to the lower left finder.
+func (c *Code) drawFormat(reserved []bool, level Level) {
+	bits := formatBits(level, c.Mask)
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(reserved, 8, i, bit(i))
+	}
+	c.set(reserved, 8, 7, bit(6))
+	c.set(reserved, 8, 8, bit(7))
+	c.set(reserved, 7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(reserved, 14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(reserved, c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(reserved, 8, c.Size-15+i, bit(i))
+	}
+	c.set(reserved, 8, c.Size-8, true)
+}
+
+// drawCodewords places the bits in the two-column zigzag that starts at the
+// lower right corner, skipping function modules and the vertical timing
+// pattern column.
+func (c *Code) drawCodewords(reserved []bool, data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if upward {
+					y = c.Size - 1 - vert
+				}
+				if reserved[y*c.Size+x] {
+					continue
+				}
+				// Remainder bits stay light.
+				if i < len(data)*8 {
+					c.modules[y*c.Size+x] = data[i>>3]>>(7-i&7)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(reserved []bool) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !reserved[y*c.Size+x] && maskBit(c.Mask, x, y) {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// codewords builds the data codewords for a single byte mode segment,
+// including the terminator and padding.
+func codewords(data []byte, version int, level Level) []byte {
+	var w bitWriter
+	w.write(0b0100, 4)
+	w.write(len(data), countBits(version))
+	for _, b := range data {
+		w.write(int(b), 8)
+	}
+
+	total := dataCodewords(version, level) * 8
+	w.write(0, min(4, total-w.n))
+	w.write(0, (8-w.n%8)%8)
+	for pad := 0xEC; w.n < total; pad ^= 0xEC ^ 0x11 {
+		w.write(pad, 8)
+	}
+	return w.buf
+}
+
+// interleave splits the data into blocks, appends the error correction
+// codewords of each block and interleaves the result.
+func interleave(data []byte, version int, level Level) []byte {
+	numBlocks := numECBlocks[level][version]
+	eccLen := eccPerBlock[level][version]
+	raw := rawModules(version) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := rsDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+		block := make([]byte, 0, shortLen+1)
+		block = append(block, data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < numShort {
+			// Keep every block the same length so the columns line
+			// up; the placeholder is skipped below.
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	out := make([]byte, 0, raw)
+	for col := 0; col <= shortLen; col++ {
+		for i, block := range blocks {
+			if col != shortLen-eccLen || i >= numShort {
+				out = append(out, block[col])
+			}
+		}
+	}
+	return out
+}
+
+func dataCodewords(version int, level Level) int {
+	return rawModules(version)/8 - eccPerBlock[level][version]*numECBlocks[level][version]
+}
+
+// rawModules returns the number of modules available for data and error
+// correction codewords, including remainder bits.
+func rawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	num := version/7 + 2
+	step := (version*8 + num*3 + 5) / (num*4 - 4) * 2
+	pos := make([]int, num)
+	pos[0] = 6
+	for i, p := num-1, version*4+10; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+func formatBits(level Level, mask int) int {
+	data := formatLevel[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+// penalty scores the masked symbol with the four rules from the standard;
+// lower is better.
+func (c *Code) penalty() int {
+	n := c.Size
+	p := 0
+
+	line := make([]bool, n)
+	for _, horizontal := range []bool{true, false} {
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				if horizontal {
+					line[b] = c.modules[a*n+b]
+				} else {
+					line[b] = c.modules[b*n+a]
+				}
+			}
+			p += linePenalty(line)
+		}
+	}
+
+	// Rule 2: 2x2 blocks of one color.
+	for y := 0; y < n-1; y++ {
+		for x := 0; x < n-1; x++ {
+			v := c.modules[y*n+x]
+			if v == c.modules[y*n+x+1] && v == c.modules[(y+1)*n+x] && v == c.modules[(y+1)*n+x+1] {
+				p += 3
+			}
+		}
+	}
+
+	// Rule 4: deviation of the dark ratio from 50%.
+	dark := 0
+	for _, m := range c.modules {
+		if m {
+			dark++
+		}
+	}
+	total := n * n
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return p + max(k, 0)*10
+}
+
+// linePenalty applies rules 1 and 3 to a single row or column.
+func linePenalty(line []bool) int {
+	p := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			p += run - 2
+		}
+		run = 1
+	}
+
+	// Rule 3: 1:1:3:1:1 finder-like pattern with four light modules on
+	// either side. Modules outside the symbol count as light.
+	at := func(i int) bool { return i >= 0 && i < len(line) && line[i] }
+	finder := [7]bool{true, false, true, true, true, false, true}
+	for i := 0; i+7 <= len(line); i++ {
+		match := true
+		for j, v := range finder {
+			if line[i+j] != v {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		before, after := true, true
+		for j := 1; j <= 4; j++ {
+			before = before && !at(i-j)
+			after = after && !at(i+6+j)
+		}
+		if before || after {
+			p += 40
+		}
+	}
+	return p
+}
+
+type bitWriter struct {
+	buf []byte
+	n   int
+}
+
+func (w *bitWriter) write(v, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if v>>i&1 != 0 {
+			w.buf[len(w.buf)-1] |= 0x80 >> (w.n % 8)
+		}
+		w.n++
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+var formatLevel = [4]int{L: 1, M: 0, Q: 3, H: 2}
+
+// Error correction codewords per block, indexed by level and version.
+var eccPerBlock = [4][41]int{
+	L: {0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	M: {0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	Q: {0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	H: {0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// Number of error correction blocks, indexed by level and version.
+var numECBlocks = [4][41]int{
+	L: {0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	M: {0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	Q: {0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	H: {0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
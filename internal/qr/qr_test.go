@@ -0,0 +1,238 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" as a 1-Q symbol, from the ISO/IEC 18004 worked
+	// example popularised by the Thonky QR tutorial.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236}
+	want := []byte{168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16}
+	if got := rsRemainder(data, rsDivisor(13)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	tests := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{L, 0, 0b111011111000100},
+		{L, 4, 0b110011000101111},
+		{M, 0, 0b101010000010010},
+		{Q, 7, 0b010101111101101},
+		{H, 3, 0b001100111010000},
+	}
+	for _, tt := range tests {
+		if got := formatBits(tt.level, tt.mask); got != tt.want {
+			t.Errorf("formatBits(%d, %d) = %015b, want %015b", tt.level, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	tests := []struct {
+		version int
+		want    int
+	}{
+		{7, 0b000111110010010100},
+		{21, 0b010101011010000011},
+		{40, 0b101000110001101001},
+	}
+	for _, tt := range tests {
+		if got := versionBits(tt.version); got != tt.want {
+			t.Errorf("versionBits(%d) = %018b, want %018b", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDataCodewords(t *testing.T) {
+	tests := []struct {
+		version int
+		level   Level
+		want    int
+	}{
+		{1, L, 19},
+		{1, H, 9},
+		{10, M, 216},
+		{40, L, 2956},
+		{40, H, 1276},
+	}
+	for _, tt := range tests {
+		if got := dataCodewords(tt.version, tt.level); got != tt.want {
+			t.Errorf("dataCodewords(%d, %d) = %d, want %d", tt.version, tt.level, got, tt.want)
+		}
+	}
+}
+
+// The golden matrices were produced by rsc.io/qr/coding with the same
+// version, level and mask.
+var goldenTests = []struct {
+	text    string
+	version int
+	level   Level
+	mask    int
+	rows    []string
+}{
+	{
+		text:    "xpass",
+		version: 1, level: M, mask: 3,
+		rows: []string{
+			"#######.###...#######",
+			"#.....#.#.##..#.....#",
+			"#.###.#..#.#..#.###.#",
+			"#.###.#.#.##..#.###.#",
+			"#.###.#...##..#.###.#",
+			"#.....#...###.#.....#",
+			"#######.#.#.#.#######",
+			"........#####........",
+			"#.##.###...##.#..#.##",
+			"...##..##.#####...###",
+			".#.####.#..#.....#.##",
+			"##..#...#..#..####.#.",
+			".#..###..#..#..#....#",
+			"........#..#..#..#.#.",
+			"#######.##.##..#.#...",
+			"#.....#.##.....#####.",
+			"#.###.#...#.#####.###",
+			"#.###.#.##.#..#.#..#.",
+			"#.###.#.###.#.##.#...",
+			"#.....#...#..#.##...#",
+			"#######.#.#..#..###..",
+		},
+	},
+	{
+		text:    "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&period=30",
+		version: 7, level: L, mask: 5,
+		rows: []string{
+			"#######..#..###...#########.#.###...#.#######",
+			"#.....#....###..#..#.#.######......#..#.....#",
+			"#.###.#....###..#.#..#...##...#....#..#.###.#",
+			"#.###.#.###.####.##.###.....####.#.##.#.###.#",
+			"#.###.#.##.#..#..##########...#.#####.#.###.#",
+			"#.....#..#..#..###..#...#....####.....#.....#",
+			"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+			"..........#.##.....##...###.###..###.........",
+			"##...###..##.....#..#######..###........##...",
+			".####..####.###.#.###.#.####.##########.#.#..",
+			"##...####.#.##....#..###.##.##.#..###.#...##.",
+			"#..#....####.#....####.......#.#....#..####..",
+			"#..#.##..##...#...##..#...#..#.##.#.######.##",
+			"##.##..#..######..#####.#...##.#....#..##.###",
+			"##...##..#..###.#.#..#####..#.#....##..#.###.",
+			"##...#.###.##...######.##.#.......#.##.#.##..",
+			".###..######.###..#..#.#.##...##.#...##..#.#.",
+			"#.#.##.##.#.#..####....###.#######.###.######",
+			".#.#.##..#..####.#..#..###.###.###.##.....#.#",
+			"#.##.......#..####...#.#####..#..##..######..",
+			"..#.#####.#.....#.#######.##.######.######.#.",
+			"#.###...######.###..#...##.##.#..#..#...###..",
+			"#..##.#.#.##.....#..#.#.#..#.#####.##.#.###..",
+			"#.#.#...#...##..#...#...##..#.#.#####...####.",
+			"##..#####..####.#...#####.#.#.#.#########...#",
+			".###.#....##.#....#.##..##..####.#.#..#...###",
+			"...##.#.#....##...#..#.#..#.#...####.##....#.",
+			".#...#.#..##.##.##...##.#.#.##.##.##..##.###.",
+			".#..####..##.#.####..#..##..#........#.##..##",
+			"..#..#.#...##..#.##.##.##...##.#.........##.#",
+			"###########.#..#.##.#.###.#..####.##..#..##.#",
+			"##.#...#..##..##.#######....##..##..#######..",
+			"#.##.#####.#####..##.....###.###........#..##",
+			"#...##.####.#.##.##..###.######.#.#.###.#....",
+			"....#.###.####.##.#.#.##.##..#.##.#.#..#.###.",
+			".####..##.#.##.##.##..######..#..##..#.#.###.",
+			"#..##.##.###..##..########.##.#..#.#######.#.",
+			"........#..####..####...####..#.#####...#...#",
+			"#######.#..##..#.#..#.#.#..#.#####.##.#.#.##.",
+			"#.....#.##..###.#...#...#.#...#....##...#.#.#",
+			"#.###.#..#..#....#..#####.#...##.#########...",
+			"#.###.#....#.#....##.#####...###.#..#...##.#.",
+			"#.###.#...##.#..##.####.##...#.###...####...#",
+			"#.....#.####......###.###....#.#........###..",
+			"#######.###..#...##.....##..#......#..###..#.",
+		},
+	},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tt := range goldenTests {
+		c := encode([]byte(tt.text), tt.version, tt.level, tt.mask)
+		if c.Size != len(tt.rows) {
+			t.Fatalf("%q: size %d, want %d", tt.text, c.Size, len(tt.rows))
+		}
+		for y, row := range tt.rows {
+			var b strings.Builder
+			for x := 0; x < c.Size; x++ {
+				if c.Black(x, y) {
+					b.WriteByte('#')
+				} else {
+					b.WriteByte('.')
+				}
+			}
+			if got := b.String(); got != row {
+				t.Errorf("%q: row %d\n got %s\nwant %s", tt.text, y, got, row)
+			}
+		}
+	}
+}
+
+func TestEncodeCapacity(t *testing.T) {
+	c, err := Encode(strings.Repeat("a", 2953), L)
+	if err != nil {
+		t.Fatalf("2953 bytes at L: %v", err)
+	}
+	if c.Version != 40 || c.Size != 177 {
+		t.Errorf("2953 bytes at L: version %d size %d, want 40 and 177", c.Version, c.Size)
+	}
+	if _, err := Encode(strings.Repeat("a", 2954), L); !errors.Is(err, ErrTooLong) {
+		t.Errorf("2954 bytes at L: err = %v, want ErrTooLong", err)
+	}
+}
+
+func TestEncodePicksSmallestVersion(t *testing.T) {
+	tests := []struct {
+		n       int
+		level   Level
+		version int
+	}{
+		{17, L, 1},
+		{18, L, 2},
+		{7, H, 1},
+		{8, H, 2},
+	}
+	for _, tt := range tests {
+		c, err := Encode(strings.Repeat("a", tt.n), tt.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Version != tt.version {
+			t.Errorf("%d bytes at level %d: version %d, want %d", tt.n, tt.level, c.Version, tt.version)
+		}
+	}
+}
+
+func TestEncodeInvalidLevel(t *testing.T) {
+	for _, level := range []Level{-1, H + 1} {
+		if _, err := Encode("x", level); !errors.Is(err, ErrLevel) {
+			t.Errorf("Encode at level %d: err = %v, want ErrLevel", level, err)
+		}
+	}
+}
+
+func TestBlackOutside(t *testing.T) {
+	c, err := Encode("x", M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][2]int{{-1, 0}, {0, -1}, {c.Size, 0}, {0, c.Size}} {
+		if c.Black(p[0], p[1]) {
+			t.Errorf("Black(%d, %d) outside the symbol is dark", p[0], p[1])
+		}
+	}
+}
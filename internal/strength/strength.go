@@ -0,0 +1,443 @@
+// Package strength estimates how hard a password is to guess.
+//
+// The estimator follows the zxcvbn idea in a reduced form: the password is
+// split into cheap-to-guess patterns (common passwords, repeats, sequences,
+// years) and brute-force characters, the cheapest split wins, the guess counts
+// of its pieces are multiplied and the result is mapped onto a 0..4 score.
+// The input is treated as untrusted: only the first MaxInput runes are
+// examined, so the cost of an estimate is bounded no matter how long the
+// first line of an entry is.
+package strength
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxInput is the number of runes examined by Estimate. Anything beyond it
+// can only make the password stronger, so ignoring it keeps the score honest.
+const MaxInput = 128
+
+// Guessing rate used for the crack time text. It models an offline attack
+// against a slow hash, the same scenario zxcvbn reports by default.
+const guessesPerSecond = 1e4
+
+// Score is the 0..4 strength bucket.
+type Score int
+
+const (
+	TooGuessable Score = iota
+	VeryGuessable
+	SomewhatGuessable
+	SafelyUnguessable
+	VeryUnguessable
+)
+
+// String returns a short human readable label for the score.
+func (s Score) String() string {
+	switch s {
+	case TooGuessable:
+		return "very weak"
+	case VeryGuessable:
+		return "weak"
+	case SomewhatGuessable:
+		return "fair"
+	case SafelyUnguessable:
+		return "strong"
+	case VeryUnguessable:
+		return "very strong"
+	default:
+		return fmt.Sprintf("Score(%d)", int(s))
+	}
+}
+
+// Result is the outcome of an estimate.
+type Result struct {
+	Score Score
+	// Log10 of the estimated number of guesses.
+	Guesses float64
+	// Estimated time to crack, e.g. "3 hours" or "centuries".
+	CrackTime string
+}
+
+// Estimate returns the strength of the password.
+func Estimate(password string) Result {
+	// Decode only what is examined; converting the whole string would cost
+	// time and memory proportional to the untrusted input.
+	runes := make([]rune, 0, min(len(password), MaxInput))
+	for _, r := range password {
+		if len(runes) == MaxInput {
+			break
+		}
+		runes = append(runes, r)
+	}
+	if len(runes) == 0 {
+		return Result{Score: TooGuessable, CrackTime: crackTime(0)}
+	}
+
+	e := estimator{
+		charGuesses: math.Log10(bruteForceCardinality(runes)),
+		units:       make(map[string]float64),
+	}
+	guesses := e.guesses(runes)
+	return Result{
+		Score:     score(guesses),
+		Guesses:   guesses,
+		CrackTime: crackTime(guesses),
+	}
+}
+
+func score(log10Guesses float64) Score {
+	switch {
+	case log10Guesses < 3:
+		return TooGuessable
+	case log10Guesses < 6:
+		return VeryGuessable
+	case log10Guesses < 8:
+		return SomewhatGuessable
+	case log10Guesses < 10:
+		return SafelyUnguessable
+	default:
+		return VeryUnguessable
+	}
+}
+
+// estimator splits a password into tokens. All guess counts are log10.
+type estimator struct {
+	// charGuesses is the cost of brute-forcing one character.
+	charGuesses float64
+	// units caches the guesses of repeated units, keyed by the unit.
+	units map[string]float64
+}
+
+// token is a pattern match ending before runes[end].
+type token struct {
+	end     int
+	guesses float64
+}
+
+// guesses returns the guesses for the cheapest split of runes into brute
+// force characters and pattern tokens.
+func (e *estimator) guesses(runes []rune) float64 {
+	type split struct {
+		guesses float64
+		tokens  int
+	}
+	best := make([]split, len(runes)+1)
+	for i := 1; i < len(best); i++ {
+		best[i].guesses = math.Inf(1)
+	}
+	for i := range runes {
+		relax := func(end int, guesses float64, tokens int) {
+			g := best[i].guesses + guesses
+			if g < best[end].guesses {
+				best[end] = split{g, best[i].tokens + tokens}
+			}
+		}
+		relax(i+1, e.charGuesses, 0)
+		for _, t := range e.tokens(runes, i) {
+			relax(t.end, t.guesses, 1)
+		}
+	}
+	// An attacker also has to guess how the tokens are arranged.
+	n := best[len(runes)]
+	return n.guesses + log10Factorial(n.tokens)
+}
+
+// tokens returns the patterns starting at runes[i].
+func (e *estimator) tokens(runes []rune, i int) []token {
+	var tokens []token
+	for end := i + minWordLen; end <= len(runes) && end-i <= maxWordLen; end++ {
+		if g, ok := wordGuesses(runes[i:end]); ok {
+			tokens = append(tokens, token{end, g})
+		}
+	}
+	// A repeated unit costs the guesses for the unit and the number of
+	// repetitions: "aaaa", "abab", "passwordpassword".
+	for size := 1; i+2*size <= len(runes); size++ {
+		if n := repeats(runes[i:], size); n > 1 {
+			g := e.unitGuesses(runes[i:i+size]) + math.Log10(float64(n))
+			tokens = append(tokens, token{i + n*size, g})
+		}
+	}
+	if n := sequenceLength(runes[i:]); n >= 3 {
+		// The first character and the length of the sequence.
+		tokens = append(tokens, token{i + n, e.charGuesses + math.Log10(float64(n))})
+	}
+	if year, ok := yearAt(runes[i:]); ok {
+		tokens = append(tokens, token{i + 4, yearGuesses(year)})
+	}
+	return tokens
+}
+
+func (e *estimator) unitGuesses(unit []rune) float64 {
+	key := string(unit)
+	if g, ok := e.units[key]; ok {
+		return g
+	}
+	g := e.guesses(unit)
+	e.units[key] = g
+	return g
+}
+
+// wordGuesses returns the guesses for word as a common password, allowing
+// for capitals and l33t substitutions, and whether it is one.
+func wordGuesses(word []rune) (float64, bool) {
+	var guesses float64
+	if rank, ok := commonRank[strings.ToLower(string(word))]; ok {
+		guesses = float64(rank)
+	} else {
+		var b strings.Builder
+		for _, r := range word {
+			if u, ok := unleet[r]; ok {
+				r = u
+			}
+			b.WriteRune(unicode.ToLower(r))
+		}
+		rank, ok := commonRank[b.String()]
+		if !ok {
+			return 0, false
+		}
+		guesses = float64(rank) * 2
+	}
+
+	letters, upper := 0, 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	switch {
+	case upper == 0:
+	case upper == letters || upper == 1 && unicode.IsUpper(word[0]):
+		// "Password" and "PASSWORD" are the usual variants.
+		guesses *= 2
+	default:
+		guesses *= math.Pow(2, float64(upper))
+	}
+	return math.Log10(guesses), true
+}
+
+// repeats counts how many times runes[:size] repeats from the start of runes.
+func repeats(runes []rune, size int) int {
+	n := 1
+	for (n+1)*size <= len(runes) && slices.Equal(runes[n*size:(n+1)*size], runes[:size]) {
+		n++
+	}
+	return n
+}
+
+// yearAt returns the year between 1900 and 2099 at the start of runes.
+func yearAt(runes []rune) (int, bool) {
+	if len(runes) < 4 {
+		return 0, false
+	}
+	year := 0
+	for _, r := range runes[:4] {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		year = year*10 + int(r-'0')
+	}
+	return year, year >= 1900 && year <= 2099
+}
+
+// yearGuesses returns the guesses for a year: people pick years close to the
+// present, so the distance from it is what has to be guessed, with at least
+// minYearSpace years.
+func yearGuesses(year int) float64 {
+	const minYearSpace = 20
+	d := year - time.Now().Year()
+	if d < 0 {
+		d = -d
+	}
+	return math.Log10(float64(max(d, minYearSpace)))
+}
+
+func log10Factorial(n int) float64 {
+	var f float64
+	for i := 2; i <= n; i++ {
+		f += math.Log10(float64(i))
+	}
+	return f
+}
+
+// sequenceLength counts the length of an ascending or descending run with a
+// step of one (abc, 987) at the start of runes. Only runs within lower case
+// letters, upper case letters or digits count; "789:;" is not a sequence.
+func sequenceLength(runes []rune) int {
+	if len(runes) < 2 {
+		return len(runes)
+	}
+	class := sequenceClass(runes[0])
+	if class == 0 || sequenceClass(runes[1]) != class {
+		return 1
+	}
+	step := runes[1] - runes[0]
+	if step != 1 && step != -1 {
+		return 1
+	}
+	n := 2
+	for n < len(runes) && runes[n]-runes[n-1] == step && sequenceClass(runes[n]) == class {
+		n++
+	}
+	return n
+}
+
+// sequenceClass returns a non-zero value identifying the alphabet r belongs
+// to, or 0 if r can't be part of a sequence.
+func sequenceClass(r rune) int {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return 1
+	case r >= 'A' && r <= 'Z':
+		return 2
+	case r >= '0' && r <= '9':
+		return 3
+	default:
+		return 0
+	}
+}
+
+func bruteForceCardinality(runes []rune) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case strings.ContainsRune(symbols, r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	c := 0
+	if lower {
+		c += 26
+	}
+	if upper {
+		c += 26
+	}
+	if digit {
+		c += 10
+	}
+	if symbol {
+		c += len(symbols)
+	}
+	if other {
+		c += 100
+	}
+	return float64(c)
+}
+
+func crackTime(log10Guesses float64) string {
+	seconds := math.Pow(10, log10Guesses) / guessesPerSecond
+	const (
+		minute  = 60
+		hour    = 60 * minute
+		day     = 24 * hour
+		month   = 31 * day
+		year    = 12 * month
+		century = 100 * year
+	)
+	plural := func(n float64, unit string) string {
+		v := int(math.Round(n))
+		if v == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", v, unit)
+	}
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds < minute:
+		return plural(seconds, "second")
+	case seconds < hour:
+		return plural(seconds/minute, "minute")
+	case seconds < day:
+		return plural(seconds/hour, "hour")
+	case seconds < month:
+		return plural(seconds/day, "day")
+	case seconds < year:
+		return plural(seconds/month, "month")
+	case seconds < century:
+		return plural(seconds/year, "year")
+	default:
+		return "centuries"
+	}
+}
+
+const symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~ "
+
+var unleet = map[rune]rune{
+	'@': 'a',
+	'4': 'a',
+	'8': 'b',
+	'(': 'c',
+	'3': 'e',
+	'6': 'g',
+	'1': 'i',
+	'!': 'i',
+	'|': 'l',
+	'0': 'o',
+	'$': 's',
+	'5': 's',
+	'7': 't',
+	'+': 't',
+	'2': 'z',
+}
+
+// commonRank maps frequent passwords and keyboard walks to their popularity
+// rank. The list is short on purpose: it catches the passwords everybody
+// tries first without embedding a large dictionary in the binary.
+var commonRank = func() map[string]int {
+	words := []string{
+		"123456", "password", "12345678", "qwerty", "123456789",
+		"12345", "1234", "111111", "1234567", "dragon",
+		"123123", "baseball", "abc123", "football", "monkey",
+		"letmein", "696969", "shadow", "master", "666666",
+		"qwertyuiop", "123321", "mustang", "1234567890", "michael",
+		"654321", "superman", "1qaz2wsx", "7777777", "121212",
+		"000000", "qazwsx", "123qwe", "killer", "trustno1",
+		"jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+		"buster", "soccer", "harley", "batman", "andrew",
+		"tigger", "sunshine", "iloveyou", "2000",
+		"charlie", "robert", "thomas", "hockey", "ranger",
+		"daniel", "starwars", "klaster", "112233", "george",
+		"computer", "michelle", "jessica", "pepper",
+		"1111", "zxcvbn", "555555", "11111111", "131313",
+		"freedom", "777777", "pass", "maggie",
+		"159753", "aaaaaa", "ginger", "princess", "joshua",
+		"cheese", "amanda", "summer", "love", "ashley",
+		"6969", "nicole", "chelsea", "biteme", "matthew",
+		"access", "yankees", "987654321", "dallas", "austin",
+		"thunder", "taylor", "matrix", "admin", "welcome",
+		"login", "passw0rd", "qwerty123", "secret", "root",
+		"toor", "changeme", "default", "guest", "asdf",
+		"asdfghjkl", "qwer", "hello", "whatever", "azerty",
+	}
+	m := make(map[string]int, len(words))
+	for i, w := range words {
+		if _, ok := m[w]; !ok {
+			m[w] = i + 1
+		}
+	}
+	return m
+}()
+
+// Bounds of the entries in commonRank, to limit the substrings looked up.
+const (
+	minWordLen = 4
+	maxWordLen = 10
+)
@@ -0,0 +1,113 @@
+package strength
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEstimateScore(t *testing.T) {
+	tests := []struct {
+		password string
+		want     Score
+	}{
+		{"", TooGuessable},
+		{"password", TooGuessable},
+		{"P@ssw0rd", TooGuessable},
+		{"123456", TooGuessable},
+		{"aaaaaaaa", TooGuessable},
+		{"abcdefgh", TooGuessable},
+		{"passwordpassword", TooGuessable},
+		{"password123456", TooGuessable},
+		{"1password", TooGuessable},
+		{"monkey1234567", TooGuessable},
+		{"iloveyou2024!!", VeryGuessable},
+		{"Password1!", VeryGuessable},
+		{"xK9#mQ2$vLp7", VeryUnguessable},
+		{"correct horse battery staple", VeryUnguessable},
+	}
+	for _, tt := range tests {
+		if got := Estimate(tt.password).Score; got != tt.want {
+			t.Errorf("Estimate(%q).Score = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTruncatesInput(t *testing.T) {
+	long := strings.Repeat("xK9#mQ2$vLp7", 1<<20/12)
+	got := Estimate(long)
+	want := Estimate(string([]rune(long)[:MaxInput]))
+	if got != want {
+		t.Errorf("Estimate(1 MB) = %+v, want %+v", got, want)
+	}
+}
+
+func TestEstimateCostIsBounded(t *testing.T) {
+	short := strings.Repeat("ab", MaxInput)
+	long := strings.Repeat("ab", 1<<22)
+	// Decoding the whole input would allocate 32 MB for the long password.
+	var before, after runtime.MemStats
+	allocated := func(password string) uint64 {
+		runtime.ReadMemStats(&before)
+		Estimate(password)
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	if s, l := allocated(short), allocated(long); l > 2*s {
+		t.Errorf("Estimate allocated %d bytes for 8 MB, %d bytes for %d runes", l, s, 2*MaxInput)
+	}
+}
+
+func TestScoreString(t *testing.T) {
+	if got := VeryUnguessable.String(); got != "very strong" {
+		t.Errorf("VeryUnguessable.String() = %q", got)
+	}
+	if got := Score(7).String(); got != "Score(7)" {
+		t.Errorf("Score(7).String() = %q", got)
+	}
+}
+
+func TestWordLenBounds(t *testing.T) {
+	for w := range commonRank {
+		if n := len([]rune(w)); n < minWordLen || n > maxWordLen {
+			t.Errorf("%q has %d runes, outside [%d, %d]", w, n, minWordLen, maxWordLen)
+		}
+	}
+}
+
+func TestSequenceLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abcd", 4},
+		{"DCBA", 4},
+		{"6789", 4},
+		{"789:;", 3},
+		{"yzAB", 2},
+		{":;<=", 1},
+		{"ace", 1},
+	}
+	for _, tt := range tests {
+		if got := sequenceLength([]rune(tt.in)); got != tt.want {
+			t.Errorf("sequenceLength(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCrackTime(t *testing.T) {
+	tests := []struct {
+		guesses float64
+		want    string
+	}{
+		{0, "less than a second"},
+		{5, "10 seconds"},
+		{8, "3 hours"},
+		{30, "centuries"},
+	}
+	for _, tt := range tests {
+		if got := crackTime(tt.guesses); got != tt.want {
+			t.Errorf("crackTime(%v) = %q, want %q", tt.guesses, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package version holds the build information shared by the command line
+// and the UI.
+//
+// Release builds set the variables with the linker: `make build` passes them
+// through -ldflags, taking the date from SOURCE_DATE_EPOCH when it is set.
+// Builds made with plain `go build` or `go install` fall back to the module
+// and VCS data the Go toolchain embeds in the binary.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags -X.
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string
+	Commit    string
+	Date      string
+	GoVersion string
+}
+
+// Get returns the build information, filling anything not set by the
+// linker from the embedded build info.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		modified := false
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+					if len(info.Commit) > 12 {
+						info.Commit = info.Commit[:12]
+					}
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && Commit == "" && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// String formats the information on one line, e.g.
+// "xpass v1.0.0 (commit 0123456789ab, built 2024-11-02T10:00:00Z, go1.22.1)".
+func (i Info) String() string {
+	s := "xpass " + i.Version + " ("
+	if i.Commit != "" {
+		s += "commit " + i.Commit + ", "
+	}
+	if i.Date != "" {
+		s += "built " + i.Date + ", "
+	}
+	return fmt.Sprintf("%s%s)", s, i.GoVersion)
+}
+
+// String returns Get().String().
+func String() string {
+	return Get().String()
+}
@@ -0,0 +1,53 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGetLinkerValues(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+	Version, Commit, Date = "v1.2.3", "0123456789ab", "2024-11-02T10:00:00Z"
+
+	got := Get()
+	want := Info{
+		Version:   "v1.2.3",
+		Commit:    "0123456789ab",
+		Date:      "2024-11-02T10:00:00Z",
+		GoVersion: runtime.Version(),
+	}
+	if got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetFallbackVersion(t *testing.T) {
+	defer func(v string) { Version = v }(Version)
+	Version = ""
+
+	// Test binaries carry no module version, so the fallback applies.
+	if got := Get().Version; got != "dev" {
+		t.Errorf("Get().Version = %q, want %q", got, "dev")
+	}
+}
+
+func TestInfoString(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{
+			Info{Version: "v1.0.0", Commit: "0123456789ab", Date: "2024-11-02T10:00:00Z", GoVersion: "go1.22.1"},
+			"xpass v1.0.0 (commit 0123456789ab, built 2024-11-02T10:00:00Z, go1.22.1)",
+		},
+		{
+			Info{Version: "dev", GoVersion: "go1.22.1"},
+			"xpass dev (go1.22.1)",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.info.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}